@@ -0,0 +1,67 @@
+package shelltoken
+
+import (
+	"regexp"
+	"strings"
+)
+
+// safeUnquoted matches strings which need no quoting at all to
+// round-trip through Parse.
+var safeUnquoted = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// Quote returns s quoted for use in a POSIX shell command line, such
+// that Parse on the result yields s back unchanged. Strings made up
+// only of safe characters are returned unquoted; everything else is
+// wrapped in single quotes, with any single quote in s closing the
+// quoted string, emitting an escaped quote, and reopening it
+// (foo'bar becomes 'foo'\''bar').
+func Quote(s string) string {
+	if s != "" && safeUnquoted.MatchString(s) {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Join quotes and joins argv into a single POSIX-shell command line
+// that Parse will split back into the original argv.
+//
+// Parse treats a leading run of "KEY=value"-shaped argv elements as
+// env assignments rather than argv, regardless of how they were
+// quoted in the source line, whenever a later element contains no
+// "=". If argv starts with such an element where one isn't meant
+// (e.g. {"FOO=bar", "echo"}), Parse's result will reclassify it as
+// env on round-trip; quoting cannot prevent this, since it only
+// affects shell syntax, not the decoded string Parse inspects. When
+// every element contains "=", Parse returns all of them as argv. Use
+// JoinEnv to encode actual env assignments explicitly.
+func Join(argv []string) string {
+	quoted := make([]string, len(argv))
+
+	for i, a := range argv {
+		quoted[i] = Quote(a)
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// JoinEnv is like Join but prepends env as "KEY=value" assignments,
+// quoting only the value portion of each.
+func JoinEnv(env, argv []string) string {
+	parts := make([]string, 0, len(env)+len(argv))
+
+	for _, e := range env {
+		if idx := strings.Index(e, "="); idx >= 0 {
+			parts = append(parts, e[:idx+1]+Quote(e[idx+1:]))
+			continue
+		}
+
+		parts = append(parts, Quote(e))
+	}
+
+	for _, a := range argv {
+		parts = append(parts, Quote(a))
+	}
+
+	return strings.Join(parts, " ")
+}
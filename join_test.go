@@ -0,0 +1,89 @@
+package shelltoken
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"foo", "foo"},
+		{"foo bar", "'foo bar'"},
+		{`foo'bar`, `'foo'\''bar'`},
+		{"", "''"},
+	}
+
+	for _, tc := range tests {
+		if got := Quote(tc.in); got != tc.want {
+			t.Errorf("Quote(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestJoinRoundTrip(t *testing.T) {
+	argv := []string{"foo", "bar baz", "it's", ""}
+
+	_, got, err := Parse(Join(argv))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, argv) {
+		t.Errorf("round trip = %v, want %v", got, argv)
+	}
+}
+
+func TestJoinEnvRoundTrip(t *testing.T) {
+	env := []string{"FOO=bar baz"}
+	argv := []string{"echo", "hi"}
+
+	gotEnv, gotArgv, err := Parse(JoinEnv(env, argv))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(gotEnv, env) {
+		t.Errorf("env = %v, want %v", gotEnv, env)
+	}
+
+	if !reflect.DeepEqual(gotArgv, argv) {
+		t.Errorf("argv = %v, want %v", gotArgv, argv)
+	}
+}
+
+// TestJoinAmbiguousEnvLikeArgvDoesNotDropArgv guards against a
+// regression where Join([]string{"FOO=bar"}) round-tripped through
+// Parse into empty env *and* empty argv: extractEnvFromArgv saw every
+// element contained "=" and fell through to a naked return. Quoting
+// can't disambiguate this case (Parse only sees the decoded string,
+// not how it was quoted), so the fallback must keep the data as argv
+// instead of discarding it.
+func TestJoinAmbiguousEnvLikeArgvDoesNotDropArgv(t *testing.T) {
+	env, argv, err := Parse(Join([]string{"FOO=bar"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(env) != 0 {
+		t.Errorf("env = %v, want empty", env)
+	}
+
+	if want := []string{"FOO=bar"}; !reflect.DeepEqual(argv, want) {
+		t.Errorf("argv = %v, want %v (data must not be dropped)", argv, want)
+	}
+}
+
+func TestExtractEnvFromArgvAllLookLikeAssignments(t *testing.T) {
+	env, args := extractEnvFromArgv([]string{"FOO=bar", "BAZ=qux"})
+
+	if env != nil {
+		t.Errorf("env = %v, want nil", env)
+	}
+
+	if want := []string{"FOO=bar", "BAZ=qux"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
@@ -0,0 +1,31 @@
+package shelltoken
+
+import "fmt"
+
+// UnterminatedQuoteError is returned by a lenient parse (see
+// Parser.AllowDangling and ParseLenient) when the input ends with an
+// open quote. Quote and Offset identify the quote character and its
+// byte offset in the parsed string, so callers can render a
+// diagnostic or keep reading input on the next line.
+type UnterminatedQuoteError struct {
+	Quote  rune
+	Offset int
+}
+
+func (e *UnterminatedQuoteError) Error() string {
+	return fmt.Sprintf("unterminated %c quote starting at byte %d", e.Quote, e.Offset)
+}
+
+// ParseLenient parses str like Parse, but instead of discarding
+// everything on an unbalanced quote, it returns the tokens parsed so
+// far, including the dangling one, along with an
+// *UnterminatedQuoteError describing where the quote began. This
+// suits interactive callers (command dispatchers, REPLs, chat bots)
+// that want to accept `!say "hello world` as a single trailing
+// argument.
+func ParseLenient(str string) (env, argv []string, err error) {
+	p := NewParser()
+	p.AllowDangling = true
+
+	return p.Parse(str)
+}
@@ -0,0 +1,70 @@
+package shelltoken
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseLenientBalancedInput(t *testing.T) {
+	env, argv, err := ParseLenient("echo hi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(env) != 0 {
+		t.Errorf("env = %v, want empty", env)
+	}
+
+	if want := []string{"echo", "hi"}; !reflect.DeepEqual(argv, want) {
+		t.Errorf("argv = %v, want %v", argv, want)
+	}
+}
+
+func TestParseLenientUnterminatedDoubleQuote(t *testing.T) {
+	_, argv, err := ParseLenient(`say "hello world`)
+
+	var uq *UnterminatedQuoteError
+	if !errors.As(err, &uq) {
+		t.Fatalf("err = %v, want *UnterminatedQuoteError", err)
+	}
+
+	if uq.Quote != '"' {
+		t.Errorf("Quote = %q, want %q", uq.Quote, '"')
+	}
+
+	if uq.Offset != 4 {
+		t.Errorf("Offset = %d, want 4", uq.Offset)
+	}
+
+	if want := []string{"say", "hello world"}; !reflect.DeepEqual(argv, want) {
+		t.Errorf("argv = %v, want %v (dangling token must be kept)", argv, want)
+	}
+}
+
+// TestParseLenientUnterminatedQuoteOffsetIgnoresLeadingWhitespace is
+// the regression test for the review repro: Parse trims leading
+// whitespace before scanning, so the raw scan offset must be shifted
+// back by that trimmed amount to land on the quote's real position in
+// the caller's string.
+func TestParseLenientUnterminatedQuoteOffsetIgnoresLeadingWhitespace(t *testing.T) {
+	_, _, err := ParseLenient(`   "hello world`)
+
+	var uq *UnterminatedQuoteError
+	if !errors.As(err, &uq) {
+		t.Fatalf("err = %v, want *UnterminatedQuoteError", err)
+	}
+
+	if uq.Offset != 3 {
+		t.Errorf("Offset = %d, want 3", uq.Offset)
+	}
+}
+
+func TestUnterminatedQuoteErrorMessage(t *testing.T) {
+	err := &UnterminatedQuoteError{Quote: '\'', Offset: 7}
+
+	want := "unterminated ' quote starting at byte 7"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
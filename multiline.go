@@ -0,0 +1,8 @@
+package shelltoken
+
+import "errors"
+
+// ErrNeedMoreInput is returned by Parse when Parser.Multiline is set
+// and the input ends with an open quote. Callers should append
+// another line (plus its newline) to the input and parse again.
+var ErrNeedMoreInput = errors.New("shelltoken: need more input")
@@ -0,0 +1,51 @@
+package shelltoken
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseLineContinuation(t *testing.T) {
+	_, argv, err := Parse("foo\\\nbar")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []string{"foobar"}; !reflect.DeepEqual(argv, want) {
+		t.Errorf("argv = %v, want %v (backslash-newline must be dropped, not joined with a space)", argv, want)
+	}
+}
+
+func TestParserMultilineNeedsMoreInput(t *testing.T) {
+	p := &Parser{ParseEnv: true, Multiline: true}
+
+	_, _, err := p.Parse(`say "hello`)
+	if !errors.Is(err, ErrNeedMoreInput) {
+		t.Errorf("err = %v, want %v", err, ErrNeedMoreInput)
+	}
+}
+
+// TestParserMultilineCompletesAcrossCalls exercises the documented
+// caller workflow: on ErrNeedMoreInput, append the next line (plus its
+// newline) to the buffered input and parse the concatenation again,
+// rather than parsing the new line on its own.
+func TestParserMultilineCompletesAcrossCalls(t *testing.T) {
+	p := &Parser{ParseEnv: true, Multiline: true}
+
+	buf := `say "hello`
+	if _, _, err := p.Parse(buf); !errors.Is(err, ErrNeedMoreInput) {
+		t.Fatalf("first line: err = %v, want %v", err, ErrNeedMoreInput)
+	}
+
+	buf += "\n" + `world"`
+
+	_, argv, err := p.Parse(buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []string{"say", "hello\nworld"}; !reflect.DeepEqual(argv, want) {
+		t.Errorf("argv = %v, want %v", argv, want)
+	}
+}
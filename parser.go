@@ -0,0 +1,436 @@
+package shelltoken
+
+import (
+	"regexp"
+	"strings"
+)
+
+// envVarPattern matches $VAR and ${VAR} references inside a token.
+var envVarPattern = regexp.MustCompile(`\$(\{[A-Za-z0-9_]+\}|[A-Za-z0-9_]+)`)
+
+// Parser controls how a command line is split into env assignments
+// and argv. The zero value parses like Parse except that ParseEnv
+// defaults to false; use NewParser to get a Parser preconfigured with
+// the same defaults as the top-level Parse function.
+type Parser struct {
+	// Dialect selects the quoting rules used by Parse. It defaults
+	// to DialectPOSIX.
+	Dialect Dialect
+
+	// Separators lists the characters which terminate an argv token.
+	// It defaults to " \t\n\r" when empty. Ignored under
+	// DialectWindows, which always splits on space and tab.
+	Separators string
+
+	// ParseEnv splits leading "KEY=value" tokens off of argv into the
+	// returned env list, the same way the top-level Parse does.
+	ParseEnv bool
+
+	// Getenv, when set, expands $VAR and ${VAR} references found in
+	// tokens. Expansion is not applied to runes parsed from inside
+	// single quotes, nor to text produced by ParseBacktick command
+	// substitution, matching how real shells never re-expand the
+	// output of a substituted command.
+	Getenv func(string) string
+
+	// ParseBacktick enables `command` substitution, replacing the
+	// backtick expression with the output of BacktickRunner.
+	ParseBacktick bool
+
+	// BacktickRunner executes the text captured between a pair of
+	// backticks and returns the replacement text. When it is nil,
+	// every substitution yields an empty string.
+	BacktickRunner func(string) (string, error)
+
+	// KeepQuotes keeps the surrounding quote characters in the
+	// resulting tokens instead of stripping them.
+	KeepQuotes bool
+
+	// StopOnError aborts the parse as soon as BacktickRunner returns
+	// an error. When false, the failed substitution is treated as
+	// an empty string and parsing continues.
+	StopOnError bool
+
+	// AllowDangling makes Parse tolerate an unterminated quote at
+	// EOF: instead of discarding everything with ErrUnbalancedQuotes,
+	// it returns the tokens parsed so far (including the dangling
+	// one) and an *UnterminatedQuoteError.
+	AllowDangling bool
+
+	// Multiline makes Parse tolerate an unterminated quote at EOF by
+	// returning ErrNeedMoreInput instead of ErrUnbalancedQuotes, so
+	// callers can append another line and parse again. It takes
+	// precedence over AllowDangling if both are set.
+	Multiline bool
+
+	pos int
+}
+
+// NewParser returns a Parser configured with the same defaults as the
+// top-level Parse function: POSIX whitespace separators and env
+// extraction enabled.
+func NewParser() *Parser {
+	return &Parser{
+		Separators: " \t\n\r",
+		ParseEnv:   true,
+	}
+}
+
+// Position returns the byte offset into the string passed to the most
+// recent call to Parse at which parsing stopped, regardless of any
+// leading/trailing whitespace Parse trimmed internally.
+func (p *Parser) Position() int {
+	return p.pos
+}
+
+// trailingState describes the quoting the scanner was still inside of
+// when it ran out of input, so callers can build an appropriate error
+// (or, for AllowDangling/Multiline, none at all).
+type trailingState struct {
+	inQuotes, inDbl, inBacktick bool
+	quoteChar                   rune
+	quoteStart                  int
+}
+
+// Parse parses line into a list of envs and argv, honoring the
+// receiver's configuration. See the top-level Parse for the meaning
+// of the returned values.
+func (p *Parser) Parse(line string) (env, argv []string, err error) {
+	if p.Dialect == DialectWindows {
+		argv, err = parseWindowsArgv(strings.TrimSpace(line))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		p.pos = len(line)
+
+		return nil, argv, nil
+	}
+
+	originalLen := len(line)
+	leadTrim := originalLen - len(strings.TrimLeft(line, " \t\n\r"))
+	line = strings.TrimSpace(line)
+
+	tokens, trailing, err := p.scan(line, false, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Position reports the offset into the string the caller passed
+	// in, not into the trimmed copy used internally, so callers can
+	// slice their own buffer without accounting for trimming.
+	p.pos = originalLen
+
+	var danglingErr error
+
+	switch {
+	case trailing.inBacktick:
+		return nil, nil, ErrUnbalancedQuotes
+	case trailing.inQuotes, trailing.inDbl:
+		switch {
+		case p.Multiline:
+			return nil, nil, ErrNeedMoreInput
+		case p.AllowDangling:
+			// trailing.quoteStart is an offset into the trimmed copy of
+			// line scanned above; add back the leading whitespace Parse
+			// trimmed so callers get an offset into the string they
+			// actually passed in, the same as Position does.
+			danglingErr = &UnterminatedQuoteError{Quote: trailing.quoteChar, Offset: trailing.quoteStart + leadTrim}
+		default:
+			return nil, nil, ErrUnbalancedQuotes
+		}
+	}
+
+	if len(tokens) == 0 {
+		tokens = []Token{{}}
+	}
+
+	argv = make([]string, len(tokens))
+	for i, tok := range tokens {
+		argv[i] = tok.Value
+	}
+
+	if p.ParseEnv {
+		env, argv = extractEnvFromArgv(argv)
+	}
+
+	return env, argv, danglingErr
+}
+
+// scan is the core tokenizing pass shared by Parse and ParseTokens. It
+// always succeeds on an unterminated quote or backtick, returning the
+// tokens parsed so far plus a trailingState describing what was left
+// open; callers decide whether that is an error. baseOffset is added
+// to every Token.StartOffset/EndOffset, so ParseTokens can report
+// offsets relative to the whole (possibly multi-line) input.
+func (p *Parser) scan(line string, recognizeOperators bool, baseOffset int) (tokens []Token, trailing trailingState, err error) {
+	separators := p.Separators
+	if separators == "" {
+		separators = " \t\n\r"
+	}
+
+	var token []rune
+	var tokenNoExpand []bool
+
+	inQuotes := false
+	inDbl := false
+	inBacktick := false
+	escaped := false
+	hadEscapes := false
+	sawSingle := false
+	sawDouble := false
+	sawBare := false
+	start := 0
+
+	var backtick []rune
+	backtickStart := 0
+
+	skipUntil := 0
+
+	ensureToken := func(pos int) {
+		if token == nil {
+			token = make([]rune, 0)
+			tokenNoExpand = make([]bool, 0)
+			start = pos
+		}
+	}
+
+	// appendRune records char as the next rune of the current token.
+	// noExpand marks it as ineligible for $VAR/${VAR} expansion: either
+	// because it came from inside single quotes, or because it was
+	// spliced in from backtick substitution output, which real shells
+	// never re-expand.
+	appendRune := func(pos int, char rune, noExpand bool) {
+		ensureToken(pos)
+		token = append(token, char)
+		tokenNoExpand = append(tokenNoExpand, noExpand)
+	}
+
+	noteQuoting := func() {
+		switch {
+		case inQuotes:
+			sawSingle = true
+		case inDbl:
+			sawDouble = true
+		default:
+			sawBare = true
+		}
+	}
+
+	addToken := func(pos int, char rune, viaEscape bool) {
+		escaped = false
+		hadEscapes = hadEscapes || viaEscape
+		appendRune(pos, char, inQuotes)
+		noteQuoting()
+	}
+
+	quoting := func() QuoteKind {
+		switch {
+		case sawSingle && !sawDouble && !sawBare:
+			return Single
+		case sawDouble && !sawSingle && !sawBare:
+			return Double
+		case !sawSingle && !sawDouble:
+			return None
+		default:
+			return Mixed
+		}
+	}
+
+	finishToken := func(endPos int) {
+		value := string(token)
+		if p.Getenv != nil {
+			value = expandEnv(token, tokenNoExpand, p.Getenv)
+		}
+
+		tokens = append(tokens, Token{
+			Value:               value,
+			Kind:                TokenWord,
+			Quoting:             quoting(),
+			HadBackslashEscapes: hadEscapes,
+			StartOffset:         baseOffset + start,
+			EndOffset:           baseOffset + endPos,
+		})
+
+		token, tokenNoExpand = nil, nil
+		hadEscapes, sawSingle, sawDouble, sawBare = false, false, false, false
+	}
+
+	for i, char := range line {
+		if i < skipUntil {
+			continue
+		}
+
+		if inBacktick {
+			switch {
+			case !escaped && char == '\\':
+				escaped = true
+				backtick = append(backtick, char)
+			case !escaped && char == '`':
+				inBacktick = false
+
+				out, rerr := runBacktick(p.BacktickRunner, string(backtick))
+				if rerr != nil && p.StopOnError {
+					return nil, trailingState{}, rerr
+				}
+
+				if rerr == nil {
+					for _, r := range out {
+						// true: substitution output is spliced in as-is,
+						// not re-expanded for $VAR/${VAR} references.
+						appendRune(backtickStart, r, true)
+						noteQuoting()
+					}
+				}
+
+				backtick = nil
+			default:
+				escaped = false
+				backtick = append(backtick, char)
+			}
+
+			continue
+		}
+
+		switch {
+		case escaped && char == '\n' && !inQuotes && !inDbl:
+			// line continuation: an unquoted backslash right before a
+			// newline is dropped along with the newline, rather than
+			// the newline being appended as a literal token rune.
+			escaped = false
+
+		case !escaped && char == '\\':
+			escaped = true
+
+			switch {
+			case inQuotes:
+				// backslashes are kept in single quotes
+				addToken(i, char, false)
+			case inDbl:
+				// or in double quotes except...
+				if len(line) > i+1 {
+					switch line[i+1] {
+					// next character is a double quote again
+					case '"':
+					// or a backslash
+					case '\\':
+					default:
+						addToken(i, char, false)
+					}
+				}
+			}
+
+		case !escaped && char == '"':
+			ensureToken(i)
+
+			if !inQuotes {
+				inDbl = !inDbl
+				if inDbl {
+					trailing.quoteChar, trailing.quoteStart = '"', i
+				}
+				if p.KeepQuotes {
+					appendRune(i, char, false)
+				}
+			} else {
+				addToken(i, char, false)
+			}
+		case !escaped && char == '\'':
+			ensureToken(i)
+
+			if !inDbl {
+				inQuotes = !inQuotes
+				if inQuotes {
+					trailing.quoteChar, trailing.quoteStart = '\'', i
+				}
+				if p.KeepQuotes {
+					appendRune(i, char, false)
+				}
+			} else {
+				addToken(i, char, false)
+			}
+		case !escaped && char == '`' && p.ParseBacktick && !inQuotes:
+			inBacktick = true
+			backtickStart = i
+			backtick = make([]rune, 0)
+		case recognizeOperators && !escaped && !inQuotes && !inDbl && char == '<' &&
+			len(line) > i+1 && line[i+1] == '<':
+			if token != nil {
+				finishToken(i)
+			}
+
+			op := "<<"
+			if len(line) > i+2 && line[i+2] == '-' {
+				op = "<<-"
+			}
+
+			tokens = append(tokens, Token{
+				Value:       op,
+				Kind:        TokenOperator,
+				StartOffset: baseOffset + i,
+				EndOffset:   baseOffset + i + len(op),
+			})
+
+			// the remaining '<' (and '-') bytes of the operator are
+			// consumed here since the range loop only sees one rune
+			// at a time.
+			skipUntil = i + len(op)
+		case !escaped && strings.ContainsRune(separators, char):
+			switch {
+			case inQuotes, inDbl:
+				addToken(i, char, false)
+			case token != nil:
+				finishToken(i)
+			}
+		default:
+			wasEscaped := escaped
+			addToken(i, char, wasEscaped)
+		}
+	}
+
+	if token != nil {
+		finishToken(len(line))
+	}
+
+	trailing.inQuotes, trailing.inDbl, trailing.inBacktick = inQuotes, inDbl, inBacktick
+
+	return tokens, trailing, nil
+}
+
+func runBacktick(runner func(string) (string, error), cmd string) (string, error) {
+	if runner == nil {
+		return "", nil
+	}
+
+	return runner(cmd)
+}
+
+// expandEnv substitutes $VAR and ${VAR} references in the runs of
+// token whose matching noExpand entry is false: that excludes runes
+// parsed from inside single quotes and runes spliced in from backtick
+// substitution output.
+func expandEnv(token []rune, noExpand []bool, getenv func(string) string) string {
+	var out strings.Builder
+
+	i := 0
+	for i < len(token) {
+		if noExpand[i] {
+			out.WriteRune(token[i])
+			i++
+
+			continue
+		}
+
+		j := i
+		for j < len(token) && !noExpand[j] {
+			j++
+		}
+
+		out.WriteString(envVarPattern.ReplaceAllStringFunc(string(token[i:j]), func(match string) string {
+			return getenv(strings.Trim(match[1:], "${}"))
+		}))
+
+		i = j
+	}
+
+	return out.String()
+}
@@ -0,0 +1,151 @@
+package shelltoken
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseDefaults(t *testing.T) {
+	env, argv, err := Parse("echo hello")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(env) != 0 {
+		t.Errorf("env = %v, want empty", env)
+	}
+
+	if want := []string{"echo", "hello"}; !reflect.DeepEqual(argv, want) {
+		t.Errorf("argv = %v, want %v", argv, want)
+	}
+}
+
+func TestParseEnvAssignments(t *testing.T) {
+	env, argv, err := Parse("FOO=bar echo hi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []string{"FOO=bar"}; !reflect.DeepEqual(env, want) {
+		t.Errorf("env = %v, want %v", env, want)
+	}
+
+	if want := []string{"echo", "hi"}; !reflect.DeepEqual(argv, want) {
+		t.Errorf("argv = %v, want %v", argv, want)
+	}
+}
+
+func TestParserGetenvExpansion(t *testing.T) {
+	p := &Parser{
+		ParseEnv: true,
+		Getenv: func(name string) string {
+			if name == "FOO" {
+				return "bar"
+			}
+
+			return ""
+		},
+	}
+
+	_, argv, err := p.Parse(`$FOO '$FOO' "$FOO"`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"bar", "$FOO", "bar"}
+	if !reflect.DeepEqual(argv, want) {
+		t.Errorf("argv = %v, want %v (single quotes must stay literal)", argv, want)
+	}
+}
+
+func TestParserBacktick(t *testing.T) {
+	p := &Parser{
+		ParseEnv:       true,
+		ParseBacktick:  true,
+		BacktickRunner: func(cmd string) (string, error) { return "OUT", nil },
+	}
+
+	_, argv, err := p.Parse("echo `cmd`")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []string{"echo", "OUT"}; !reflect.DeepEqual(argv, want) {
+		t.Errorf("argv = %v, want %v", argv, want)
+	}
+}
+
+// TestParserBacktickOutputNotReexpanded is the regression test for
+// the review repro: real shells never re-expand the output of a
+// command substitution, so a BacktickRunner returning text containing
+// "$VAR" must not have that text passed back through Getenv.
+func TestParserBacktickOutputNotReexpanded(t *testing.T) {
+	p := &Parser{
+		ParseEnv:      true,
+		ParseBacktick: true,
+		BacktickRunner: func(cmd string) (string, error) {
+			return "$HOME literal", nil
+		},
+		Getenv: func(name string) string {
+			t.Errorf("Getenv(%q) called; backtick output must not be re-expanded", name)
+			return ""
+		},
+	}
+
+	_, argv, err := p.Parse("echo `cmd`")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []string{"echo", "$HOME literal"}; !reflect.DeepEqual(argv, want) {
+		t.Errorf("argv = %v, want %v", argv, want)
+	}
+}
+
+func TestParserBacktickStopOnError(t *testing.T) {
+	boom := errors.New("boom")
+	runner := func(string) (string, error) { return "", boom }
+
+	strict := &Parser{ParseEnv: true, ParseBacktick: true, BacktickRunner: runner, StopOnError: true}
+	if _, _, err := strict.Parse("echo `x`"); !errors.Is(err, boom) {
+		t.Errorf("StopOnError=true: err = %v, want %v", err, boom)
+	}
+
+	lenient := &Parser{ParseEnv: true, ParseBacktick: true, BacktickRunner: runner, StopOnError: false}
+
+	_, argv, err := lenient.Parse("echo `x`")
+	if err != nil {
+		t.Fatalf("StopOnError=false: unexpected error: %v", err)
+	}
+
+	if want := []string{"echo"}; !reflect.DeepEqual(argv, want) {
+		t.Errorf("StopOnError=false: argv = %v, want %v", argv, want)
+	}
+}
+
+func TestParserKeepQuotes(t *testing.T) {
+	p := &Parser{ParseEnv: true, KeepQuotes: true}
+
+	_, argv, err := p.Parse(`"foo"`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []string{`"foo"`}; !reflect.DeepEqual(argv, want) {
+		t.Errorf("argv = %v, want %v", argv, want)
+	}
+}
+
+func TestParserPositionIsRelativeToOriginalInput(t *testing.T) {
+	p := NewParser()
+	line := "  foo bar  "
+
+	if _, _, err := p.Parse(line); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := p.Position(), len(line); got != want {
+		t.Errorf("Position() = %d, want %d (length of the untrimmed input)", got, want)
+	}
+}
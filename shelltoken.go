@@ -3,6 +3,13 @@
 // The shelltoken package splits a command line into token by whitespace
 // characters while honoring single and double quotes.
 // Backslashes and escaped quotes are supported as well.
+//
+// Parse covers the common case. Callers which need to customize the
+// separator set, expand environment variables, run backtick
+// sub-commands or otherwise tweak parsing behavior should construct a
+// Parser instead. Callers which need to know how each token was
+// quoted, or where it sat in the original string, should use
+// ParseTokens, which shares its tokenizer with Parser.Parse.
 package shelltoken
 
 import (
@@ -21,98 +28,7 @@ var ErrUnbalancedQuotes = errors.New("unbalanced quotes")
 // are the arguments.
 // An unsuccessful parse will return an error.
 func Parse(str string) (env, argv []string, err error) {
-	var token []rune
-
-	separator := " \t\n\r"
-	inQuotes := false
-	inDbl := false
-	escaped := false
-	str = strings.TrimSpace(str)
-
-	addToken := func(char rune) {
-		escaped = false
-
-		if token == nil {
-			token = make([]rune, 0)
-		}
-
-		token = append(token, char)
-	}
-
-	for pos, char := range str {
-		switch {
-		case !escaped && char == '\\':
-			escaped = true
-
-			switch {
-			case inQuotes:
-				// backslashes are kept in single quotes
-				addToken(char)
-			case inDbl:
-				// or in double quotes except...
-				if len(str) > pos {
-					switch str[pos+1] {
-					// next character is a double quote again
-					case '"':
-					// or a backslash
-					case '\\':
-					default:
-						addToken(char)
-					}
-				}
-			}
-
-		case !escaped && char == '"':
-			if token == nil {
-				token = make([]rune, 0)
-			}
-
-			if !inQuotes {
-				inDbl = !inDbl
-			} else {
-				addToken(char)
-			}
-		case !escaped && char == '\'':
-			if token == nil {
-				token = make([]rune, 0)
-			}
-
-			if !inDbl {
-				inQuotes = !inQuotes
-			} else {
-				addToken(char)
-			}
-		case !escaped && strings.ContainsRune(separator, char):
-			switch {
-			case inQuotes, inDbl:
-				addToken(char)
-			case token != nil:
-				argv = append(argv, string(token))
-				token = nil
-			}
-		default:
-			addToken(char)
-		}
-	}
-
-	if token == nil {
-		// append empty token if no token found so far
-		argv = append(argv, "")
-	} else {
-		// append last token
-		argv = append(argv, string(token))
-	}
-
-	switch {
-	case inQuotes:
-		return nil, nil, ErrUnbalancedQuotes
-	case inDbl:
-		return nil, nil, ErrUnbalancedQuotes
-	}
-
-	env, argv = extractEnvFromArgv(argv)
-
-	return env, argv, nil
+	return NewParser().Parse(str)
 }
 
 func extractEnvFromArgv(argv []string) (envs, args []string) {
@@ -122,5 +38,8 @@ func extractEnvFromArgv(argv []string) (envs, args []string) {
 		}
 	}
 
-	return
+	// No element lacks an "=", so there is no command word marking
+	// where env assignments end and argv begins. Rather than discard
+	// argv entirely, treat all of it as argv.
+	return nil, argv
 }
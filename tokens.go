@@ -0,0 +1,190 @@
+package shelltoken
+
+import "strings"
+
+// QuoteKind records how a Token's value was quoted in the source.
+type QuoteKind int
+
+const (
+	// None means the token contained no quoted runes.
+	None QuoteKind = iota
+
+	// Single means every rune in the token came from inside single
+	// quotes.
+	Single
+
+	// Double means every rune in the token came from inside double
+	// quotes.
+	Double
+
+	// Mixed means the token mixes quoted and unquoted runes, or
+	// single- and double-quoted runs.
+	Mixed
+)
+
+// TokenKind classifies a Token returned by ParseTokens.
+type TokenKind int
+
+const (
+	// TokenWord is a plain argv word.
+	TokenWord TokenKind = iota
+
+	// TokenOperator is a shell operator, currently only "<<" and
+	// "<<-" (here-doc redirection).
+	TokenOperator
+
+	// TokenHeredoc is the literal body collected for a preceding
+	// "<<"/"<<-" operator, up to its terminating tag line.
+	TokenHeredoc
+)
+
+// Token is a single element of a ParseTokens result: a word, a shell
+// operator, or a captured here-doc body.
+type Token struct {
+	Value string
+	Kind  TokenKind
+
+	// Quoting describes how Value was quoted in the source. It is
+	// always None for TokenOperator and TokenHeredoc tokens.
+	Quoting QuoteKind
+
+	// HadBackslashEscapes reports whether Value contains at least
+	// one rune that reached the token via a backslash escape.
+	HadBackslashEscapes bool
+
+	// StartOffset and EndOffset are the byte offsets in the parsed
+	// string spanned by this token, including any surrounding quote
+	// characters.
+	StartOffset, EndOffset int
+}
+
+// ParseTokens splits str into Tokens using the default Parser,
+// recognizing "<<" and "<<-" here-doc operators in addition to plain
+// words. See Parser.ParseTokens.
+func ParseTokens(str string) ([]Token, error) {
+	return NewParser().ParseTokens(str)
+}
+
+// ParseTokens splits str into Tokens, preserving quoting metadata for
+// each word and honoring minimal here-doc syntax: "cmd <<TAG\nbody\n
+// TAG\n" yields an operator token for "<<", a word token for "TAG"
+// and a single TokenHeredoc token carrying the body. "<<-" strips
+// leading tabs from each following line only when comparing it with
+// the tag; the body itself is kept verbatim, tabs included.
+//
+// A quote or backtick left open at the end of a physical line is not
+// treated as closed: ParseTokens keeps pulling in subsequent lines
+// (rejoined with the "\n" that split ate) and rescanning until it
+// finds the matching close or runs out of input, the same as Parse.
+// If the input ends first, it returns ErrUnbalancedQuotes, or, per
+// the receiver's AllowDangling/Multiline settings, an
+// *UnterminatedQuoteError or ErrNeedMoreInput instead.
+func (p *Parser) ParseTokens(str string) ([]Token, error) {
+	lines := strings.Split(str, "\n")
+	offset := 0
+
+	var tokens []Token
+
+	for li := 0; li < len(lines); li++ {
+		segStart := offset
+		segment := lines[li]
+
+		var lineTokens []Token
+		var trailing trailingState
+		var err error
+
+		for {
+			lineTokens, trailing, err = p.scan(segment, true, segStart)
+			if err != nil {
+				return tokens, err
+			}
+
+			if !trailing.inQuotes && !trailing.inDbl && !trailing.inBacktick {
+				break
+			}
+
+			if li+1 >= len(lines) {
+				switch {
+				case trailing.inBacktick:
+					return nil, ErrUnbalancedQuotes
+				case p.Multiline:
+					return nil, ErrNeedMoreInput
+				case p.AllowDangling:
+					return append(tokens, lineTokens...), &UnterminatedQuoteError{Quote: trailing.quoteChar, Offset: segStart + trailing.quoteStart}
+				default:
+					return nil, ErrUnbalancedQuotes
+				}
+			}
+
+			// The quote/backtick is still open at the end of this
+			// physical line, so the newline strings.Split ate is
+			// really part of the quoted text rather than a separator.
+			// Pull in the next line and rescan the whole span together.
+			offset += len(lines[li]) + 1
+			li++
+			segment += "\n" + lines[li]
+		}
+
+		for ti := 0; ti < len(lineTokens); ti++ {
+			tok := lineTokens[ti]
+			tokens = append(tokens, tok)
+
+			if tok.Kind != TokenOperator {
+				continue
+			}
+
+			strip := tok.Value == "<<-"
+
+			var tag string
+			if ti+1 < len(lineTokens) {
+				ti++
+				tag = lineTokens[ti].Value
+				tokens = append(tokens, lineTokens[ti])
+			}
+
+			bodyStart := offset + len(lines[li]) + 1
+
+			var body []string
+
+			j := li + 1
+			for ; j < len(lines); j++ {
+				cmp := lines[j]
+				if strip {
+					cmp = strings.TrimLeft(cmp, "\t")
+				}
+
+				if cmp == tag {
+					break
+				}
+
+				body = append(body, lines[j])
+			}
+
+			value := strings.Join(body, "\n")
+			tokens = append(tokens, Token{
+				Value:       value,
+				Kind:        TokenHeredoc,
+				StartOffset: bodyStart,
+				EndOffset:   bodyStart + len(value),
+			})
+
+			// Advance offset past the consumed body lines so it lands
+			// on the start of the terminator line (li=j); the loop
+			// tail below then advances past that line like any other.
+			offset = bodyStart
+			for _, l := range body {
+				offset += len(l) + 1
+			}
+
+			li = j
+		}
+
+		if li >= len(lines) {
+			break
+		}
+
+		offset += len(lines[li]) + 1
+	}
+
+	return tokens, nil
+}
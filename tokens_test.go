@@ -0,0 +1,111 @@
+package shelltoken
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTokensWords(t *testing.T) {
+	tokens, err := ParseTokens(`foo "bar baz" 'qux'`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Token{
+		{Value: "foo", Kind: TokenWord, Quoting: None, StartOffset: 0, EndOffset: 3},
+		{Value: "bar baz", Kind: TokenWord, Quoting: Double, StartOffset: 4, EndOffset: 13},
+		{Value: "qux", Kind: TokenWord, Quoting: Single, StartOffset: 14, EndOffset: 19},
+	}
+
+	if !reflect.DeepEqual(tokens, want) {
+		t.Errorf("tokens = %#v, want %#v", tokens, want)
+	}
+}
+
+// TestParseTokensHeredocOffsets is the regression test for the review
+// repro: offsets for tokens following a here-doc body were computed
+// from only the terminator line's length, not the full consumed body,
+// and the heredoc token itself never got a real offset.
+func TestParseTokensHeredocOffsets(t *testing.T) {
+	input := "cmd <<EOF\nline1\nline2\nEOF\nnext arg\n"
+
+	tokens, err := ParseTokens(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Token{
+		{Value: "cmd", Kind: TokenWord, Quoting: None, StartOffset: 0, EndOffset: 3},
+		{Value: "<<", Kind: TokenOperator, Quoting: None, StartOffset: 4, EndOffset: 6},
+		{Value: "EOF", Kind: TokenWord, Quoting: None, StartOffset: 6, EndOffset: 9},
+		{Value: "line1\nline2", Kind: TokenHeredoc, Quoting: None, StartOffset: 10, EndOffset: 21},
+		{Value: "next", Kind: TokenWord, Quoting: None, StartOffset: 26, EndOffset: 30},
+		{Value: "arg", Kind: TokenWord, Quoting: None, StartOffset: 31, EndOffset: 34},
+	}
+
+	if !reflect.DeepEqual(tokens, want) {
+		t.Errorf("tokens = %#v, want %#v", tokens, want)
+	}
+
+	for _, tok := range tokens {
+		if tok.Kind != TokenWord && tok.Kind != TokenHeredoc {
+			continue
+		}
+
+		if got := input[tok.StartOffset:tok.EndOffset]; tok.Kind == TokenWord && got != tok.Value {
+			t.Errorf("input[%d:%d] = %q, want token value %q", tok.StartOffset, tok.EndOffset, got, tok.Value)
+		}
+	}
+}
+
+// TestParseTokensQuoteSpansLines is the regression test for the
+// review repro: a quote left open at the end of a physical line is
+// not actually closed, so the embedded newline must stay part of the
+// token and Quoting must still reflect the whole quoted run.
+func TestParseTokensQuoteSpansLines(t *testing.T) {
+	tokens, err := ParseTokens("say \"hello\nworld\"")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Token{
+		{Value: "say", Kind: TokenWord, Quoting: None, StartOffset: 0, EndOffset: 3},
+		{Value: "hello\nworld", Kind: TokenWord, Quoting: Double, StartOffset: 4, EndOffset: 17},
+	}
+
+	if !reflect.DeepEqual(tokens, want) {
+		t.Errorf("tokens = %#v, want %#v", tokens, want)
+	}
+}
+
+func TestParseTokensUnterminatedQuoteIsAnError(t *testing.T) {
+	_, err := ParseTokens(`say "hello world`)
+	if err != ErrUnbalancedQuotes {
+		t.Errorf("err = %v, want %v", err, ErrUnbalancedQuotes)
+	}
+}
+
+func TestParseTokensHeredocDash(t *testing.T) {
+	input := "cmd <<-EOF\n\tline1\nEOF\n"
+
+	tokens, err := ParseTokens(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var heredoc *Token
+
+	for i := range tokens {
+		if tokens[i].Kind == TokenHeredoc {
+			heredoc = &tokens[i]
+		}
+	}
+
+	if heredoc == nil {
+		t.Fatalf("no heredoc token found in %#v", tokens)
+	}
+
+	if want := "\tline1"; heredoc.Value != want {
+		t.Errorf("heredoc value = %q, want %q (body is kept verbatim; only the tag comparison strips tabs)", heredoc.Value, want)
+	}
+}
@@ -0,0 +1,109 @@
+package shelltoken
+
+import "strings"
+
+// Dialect selects the quoting and escaping rules a Parser uses.
+type Dialect int
+
+const (
+	// DialectPOSIX is the default dialect used by Parse: single and
+	// double quotes, backslash escaping, whitespace separators.
+	DialectPOSIX Dialect = iota
+
+	// DialectWindows implements cmd.exe / CommandLineToArgvW
+	// semantics: only double quotes are recognized, backslashes are
+	// literal except when they precede a quote, and separators are
+	// limited to space and tab.
+	DialectWindows
+)
+
+// ParseWindows parses str using cmd.exe / CommandLineToArgvW quoting
+// rules instead of the POSIX rules used by Parse. It never extracts
+// env assignments, since cmd.exe has no such concept; env is always
+// nil.
+func ParseWindows(str string) (env, argv []string, err error) {
+	return (&Parser{Dialect: DialectWindows}).Parse(str)
+}
+
+// parseWindowsArgv splits line the way CommandLineToArgvW does.
+//
+// Backslashes are literal except immediately before a '"': 2n
+// backslashes collapse to n literal backslashes and toggle quoted
+// mode, while 2n+1 backslashes collapse to n literal backslashes
+// followed by one literal '"'. Two adjacent quotes inside a quoted
+// string produce a single literal '"' and stay in quoted mode.
+// Separators are space and tab only.
+func parseWindowsArgv(line string) (argv []string, err error) {
+	runes := []rune(line)
+	n := len(runes)
+	i := 0
+	inQuotes := false
+	appended := false
+
+	isSeparator := func(r rune) bool {
+		return r == ' ' || r == '\t'
+	}
+
+	for {
+		for i < n && !inQuotes && isSeparator(runes[i]) {
+			i++
+		}
+
+		if i >= n {
+			break
+		}
+
+		var token []rune
+		appended = true
+
+		for i < n && (inQuotes || !isSeparator(runes[i])) {
+			switch runes[i] {
+			case '\\':
+				j := i
+				for j < n && runes[j] == '\\' {
+					j++
+				}
+
+				numBackslash := j - i
+
+				if j < n && runes[j] == '"' {
+					token = append(token, []rune(strings.Repeat(`\`, numBackslash/2))...)
+
+					if numBackslash%2 == 1 {
+						token = append(token, '"')
+					} else {
+						inQuotes = !inQuotes
+					}
+
+					i = j + 1
+				} else {
+					token = append(token, []rune(strings.Repeat(`\`, numBackslash))...)
+					i = j
+				}
+			case '"':
+				if inQuotes && i+1 < n && runes[i+1] == '"' {
+					token = append(token, '"')
+					i += 2
+				} else {
+					inQuotes = !inQuotes
+					i++
+				}
+			default:
+				token = append(token, runes[i])
+				i++
+			}
+		}
+
+		argv = append(argv, string(token))
+	}
+
+	if inQuotes {
+		return nil, ErrUnbalancedQuotes
+	}
+
+	if !appended {
+		argv = append(argv, "")
+	}
+
+	return argv, nil
+}
@@ -0,0 +1,68 @@
+package shelltoken
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseWindowsBasic(t *testing.T) {
+	env, argv, err := ParseWindows(`foo "bar baz" qux`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if env != nil {
+		t.Errorf("env = %v, want nil (cmd.exe has no env assignment syntax)", env)
+	}
+
+	if want := []string{"foo", "bar baz", "qux"}; !reflect.DeepEqual(argv, want) {
+		t.Errorf("argv = %v, want %v", argv, want)
+	}
+}
+
+// TestParseWindowsCanonicalExamples checks the backslash/quote vectors
+// from the CommandLineToArgvW documentation.
+func TestParseWindowsCanonicalExamples(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{
+			name: "even backslashes before non-quote",
+			in:   `a\\b d"e f"g h`,
+			want: []string{`a\\b`, "de fg", "h"},
+		},
+		{
+			name: "odd backslashes before quote",
+			in:   `a\\\"b c d`,
+			want: []string{`a\"b`, "c", "d"},
+		},
+		{
+			name: "even backslashes before quote toggles quoting",
+			in:   `a\\\\"b c" d e`,
+			want: []string{`a\\b c`, "d", "e"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, argv, err := ParseWindows(tc.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(argv, tc.want) {
+				t.Errorf("ParseWindows(%q) = %v, want %v", tc.in, argv, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseWindowsUnterminatedQuote(t *testing.T) {
+	_, _, err := ParseWindows(`"unterminated`)
+	if !errors.Is(err, ErrUnbalancedQuotes) {
+		t.Errorf("err = %v, want %v", err, ErrUnbalancedQuotes)
+	}
+}